@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// jwtSecret reads the JWT HMAC signing secret from the environment, falling
+// back to an insecure development default.
+func jwtSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte("dev-insecure-jwt-secret-change-me")
+}
+
+// apiUserClaims is the JWT payload issued on login.
+type apiUserClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// apiRouter builds the versioned JSON API mounted under /api/v1.
+func (a *app) apiRouter() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/v1/signup", a.apiSignupHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/login", a.apiLoginHandler).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/books", a.apiAuthRequired(a.apiListBooksHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/books", a.apiAuthRequired(a.apiCreateBookHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/books/{id}", a.apiAuthRequired(a.apiGetBookHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/books/{id}", a.apiAuthRequired(a.apiUpdateBookHandler)).Methods(http.MethodPut)
+	r.HandleFunc("/api/v1/books/{id}", a.apiAuthRequired(a.apiDeleteBookHandler)).Methods(http.MethodDelete)
+	return r
+}
+
+// apiError writes a JSON error body with the given status code.
+func apiError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// apiAuthRequired lets a request through if it carries a valid JWT bearer
+// token, otherwise it responds 401. The API intentionally doesn't honor the
+// browser session cookie: csrfExemptAPI skips CSRF checks for all of
+// /api/v1/, which is only safe because a bearer token isn't something a
+// browser attaches automatically, unlike a cookie.
+func (a *app) apiAuthRequired(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		if auth := r.Header.Get("Authorization"); len(auth) > len(prefix) && auth[:len(prefix)] == prefix {
+			token, err := jwt.ParseWithClaims(auth[len(prefix):], &apiUserClaims{}, func(*jwt.Token) (interface{}, error) {
+				return jwtSecret(), nil
+			})
+			if err == nil && token.Valid {
+				next(w, r)
+				return
+			}
+		}
+
+		apiError(w, http.StatusUnauthorized, "authentication required")
+	}
+}
+
+func (a *app) apiSignupHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "error creating user")
+		return
+	}
+
+	user := User{Name: req.Name, Email: req.Email, Password: hashedPassword}
+	if err := a.users.CreateUser(r.Context(), user); err == ErrUserExists {
+		apiError(w, http.StatusConflict, "email already registered")
+		return
+	} else if err != nil {
+		apiError(w, http.StatusInternalServerError, "error creating user")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (a *app) apiLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := a.users.GetUserByEmail(r.Context(), req.Email)
+	if err != nil || !checkPassword(user.Password, req.Password) {
+		apiError(w, http.StatusUnauthorized, "invalid email or password")
+		return
+	}
+
+	claims := apiUserClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret())
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to issue token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+// booksPage is the JSON response for the list endpoint: a page of books, the
+// (capped) total matching count, and a next-cursor token derived from the
+// last ObjectID in the page. Pass it back as ?cursor= to keyset-page through
+// the default ID order; ?page=/?pageSize= remain available for jumping to
+// an arbitrary offset.
+type booksPage struct {
+	Books      []Book `json:"books"`
+	TotalCount int64  `json:"total_count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (a *app) apiListBooksHandler(w http.ResponseWriter, r *http.Request) {
+	query := parseBookQuery(r.URL.Query())
+
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		cursor, err := primitive.ObjectIDFromHex(c)
+		if err != nil {
+			apiError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		query.Cursor = cursor
+	}
+
+	result, err := a.books.ListBooks(r.Context(), query)
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to list books")
+		return
+	}
+
+	_, pageSize := normalizePaging(query.Page, query.PageSize)
+	response := booksPage{Books: result.Books, TotalCount: result.TotalCount}
+	if len(result.Books) == pageSize {
+		response.NextCursor = result.Books[len(result.Books)-1].ID.Hex()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (a *app) apiGetBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	book, err := a.books.GetBook(r.Context(), id)
+	if err == ErrBookNotFound {
+		apiError(w, http.StatusNotFound, "book not found")
+		return
+	} else if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to get book")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(book)
+}
+
+func (a *app) apiCreateBookHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string  `json:"name"`
+		Author string  `json:"author"`
+		Cost   float64 `json:"cost"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	book, err := a.books.AddBook(r.Context(), Book{Name: req.Name, Author: req.Author, Cost: req.Cost})
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to create book")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(book)
+}
+
+func (a *app) apiUpdateBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	existing, err := a.books.GetBook(r.Context(), id)
+	if err == ErrBookNotFound {
+		apiError(w, http.StatusNotFound, "book not found")
+		return
+	} else if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to get book")
+		return
+	}
+
+	var req struct {
+		Name   string  `json:"name"`
+		Author string  `json:"author"`
+		Cost   float64 `json:"cost"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	book := Book{ID: id, Name: req.Name, Author: req.Author, Cost: req.Cost, ImageURL: existing.ImageURL, Description: existing.Description}
+	if err := a.books.UpdateBook(r.Context(), book); err == ErrBookNotFound {
+		apiError(w, http.StatusNotFound, "book not found")
+		return
+	} else if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to update book")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(book)
+}
+
+func (a *app) apiDeleteBookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	if err := a.books.DeleteBook(r.Context(), id); err == ErrBookNotFound {
+		apiError(w, http.StatusNotFound, "book not found")
+		return
+	} else if err != nil {
+		apiError(w, http.StatusInternalServerError, "failed to delete book")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
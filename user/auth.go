@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gorilla/sessions"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sessionName is the cookie name used for the signed session store.
+const sessionName = "golang-login-session"
+
+// sessionUserKey is the session value holding the logged-in user's email.
+const sessionUserKey = "email"
+
+// sessionStore signs session cookies issued on login; it is given only a
+// signing key, so the cookie values are not encrypted. The
+// server only ever serves over plain HTTP (see main.go), so the cookie must
+// not be marked Secure or the browser will silently refuse to store it;
+// gorilla/sessions v1.4.0 defaults to Secure otherwise.
+var sessionStore = newSessionStore()
+
+func newSessionStore() *sessions.CookieStore {
+	store := sessions.NewCookieStore(sessionSigningKey())
+	store.Options = &sessions.Options{
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+	}
+	return store
+}
+
+// sessionSigningKey reads the session signing key from the environment so
+// deployments can override the insecure development default.
+func sessionSigningKey() []byte {
+	if key := os.Getenv("SESSION_KEY"); key != "" {
+		return []byte(key)
+	}
+	return []byte("dev-insecure-session-key-change-me")
+}
+
+// csrfAuthKey reads the CSRF signing key from the environment, falling back
+// to an insecure development default.
+func csrfAuthKey() []byte {
+	if key := os.Getenv("CSRF_KEY"); key != "" {
+		return []byte(key)
+	}
+	return []byte("dev-insecure-csrf-key-32-bytes-long!")
+}
+
+// authRequired wraps a handler so it only runs for requests with a valid
+// session, redirecting to the login page otherwise.
+func authRequired(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, _ := sessionStore.Get(r, sessionName)
+		if _, ok := session.Values[sessionUserKey]; !ok {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// hashPassword hashes a plaintext password for storage.
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// checkPassword reports whether password matches the stored bcrypt hash.
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
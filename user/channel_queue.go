@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// channelQueue is an in-process, channel-backed Publisher.
+type channelQueue struct {
+	messages chan string
+}
+
+func newChannelQueue() *channelQueue {
+	return &channelQueue{messages: make(chan string, 100)}
+}
+
+func (q *channelQueue) Publish(ctx context.Context, bookID string) error {
+	select {
+	case q.messages <- bookID:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *channelQueue) Subscribe(ctx context.Context, handler func(ctx context.Context, bookID string) error) error {
+	for {
+		select {
+		case bookID, ok := <-q.messages:
+			if !ok {
+				return nil
+			}
+			if err := handler(ctx, bookID); err != nil {
+				log.Printf("worker: enrich %s: %v", bookID, err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *channelQueue) Close() error {
+	close(q.messages)
+	return nil
+}
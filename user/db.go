@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrUserNotFound is returned when no user matches the given email.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUserExists is returned when a user with the given email already exists.
+var ErrUserExists = errors.New("user already exists")
+
+// ErrBookNotFound is returned when no book matches the given id.
+var ErrBookNotFound = errors.New("book not found")
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+	// maxCountCap bounds the total-count query so a huge catalog can't turn
+	// "how many results" into a full table scan.
+	maxCountCap = 1000
+)
+
+// BookQuery describes a search/pagination/sort request against the book
+// listing, shared by the HTML and JSON API handlers.
+//
+// Pagination can be driven two ways: Page/PageSize for offset-based paging
+// (used by the HTML pager, which needs page numbers to render), or Cursor
+// for keyset paging by ObjectID (the JSON API's documented next-cursor
+// contract). When Cursor is set it takes precedence over Page and Sort:
+// results are filtered to IDs greater than the cursor and ordered by _id
+// ascending, since a cursor only has a stable meaning against insertion
+// order.
+type BookQuery struct {
+	Search   string
+	Page     int
+	PageSize int
+	Sort     string
+	Cursor   primitive.ObjectID
+}
+
+// BookListResult is a page of books plus the (capped) total matching count,
+// so callers can render "X of Y results".
+type BookListResult struct {
+	Books      []Book
+	TotalCount int64
+}
+
+// BookDatabase is the storage interface the book handlers depend on.
+type BookDatabase interface {
+	ListBooks(ctx context.Context, query BookQuery) (BookListResult, error)
+	GetBook(ctx context.Context, id primitive.ObjectID) (Book, error)
+	AddBook(ctx context.Context, book Book) (Book, error)
+	UpdateBook(ctx context.Context, book Book) error
+	DeleteBook(ctx context.Context, id primitive.ObjectID) error
+}
+
+// UserDatabase is the storage interface the auth handlers depend on.
+type UserDatabase interface {
+	CreateUser(ctx context.Context, user User) error
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+}
+
+// newDatabases builds the BookDatabase/UserDatabase pair selected by the
+// DB_BACKEND environment variable ("mongo", "memory", or "mysql"). It
+// defaults to "mongo" to match prior behavior, and returns a cleanup func
+// that releases any connection the backend opened.
+func newDatabases(ctx context.Context) (BookDatabase, UserDatabase, func(), error) {
+	switch backend := os.Getenv("DB_BACKEND"); backend {
+	case "", "mongo":
+		return newMongoDB(ctx)
+	case "memory":
+		db := newMemoryDB()
+		return db, db, func() {}, nil
+	case "mysql":
+		return newMySQLDB(ctx)
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown DB_BACKEND %q", backend)
+	}
+}
+
+// parseBookQuery builds a BookQuery from the ?q=, ?page=, ?pageSize=, and
+// ?sort= parameters shared by the HTML and JSON book listing endpoints.
+func parseBookQuery(values url.Values) BookQuery {
+	page, _ := strconv.Atoi(values.Get("page"))
+	pageSize, _ := strconv.Atoi(values.Get("pageSize"))
+	return BookQuery{
+		Search:   values.Get("q"),
+		Page:     page,
+		PageSize: pageSize,
+		Sort:     values.Get("sort"),
+	}
+}
+
+// normalizePaging clamps page/pageSize to sane defaults and bounds.
+func normalizePaging(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// bookMatchesSearch is the in-process search fallback used by backends
+// without a native full-text/LIKE query (currently memoryDB).
+func bookMatchesSearch(book Book, search string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	return strings.Contains(strings.ToLower(book.Name), search) ||
+		strings.Contains(strings.ToLower(book.Author), search)
+}
+
+// sortBooks orders books in place by the "?sort=" parameter (one of
+// name/author/cost, optionally prefixed with "-" for descending). Unknown or
+// empty sort fields fall back to ID order.
+func sortBooks(books []Book, sortParam string) {
+	field := sortParam
+	desc := strings.HasPrefix(field, "-")
+	if desc {
+		field = field[1:]
+	}
+
+	less := func(i, j int) bool {
+		switch field {
+		case "name":
+			return books[i].Name < books[j].Name
+		case "author":
+			return books[i].Author < books[j].Author
+		case "cost":
+			return books[i].Cost < books[j].Cost
+		default:
+			return books[i].ID.Hex() < books[j].ID.Hex()
+		}
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		if desc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
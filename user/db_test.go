@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// newTestBookDatabase returns the named BookDatabase implementation. Backends
+// that need a live server are skipped when unreachable so `go test` stays
+// usable without Mongo/MySQL running.
+func newTestBookDatabase(t *testing.T, name string) BookDatabase {
+	t.Helper()
+
+	switch name {
+	case "memory":
+		return newMemoryDB()
+	case "mongo":
+		db, _, cleanup, err := newMongoDB(context.Background())
+		if err != nil {
+			t.Skipf("mongo not available: %v", err)
+		}
+		t.Cleanup(cleanup)
+		return db
+	case "mysql":
+		db, _, cleanup, err := newMySQLDB(context.Background())
+		if err != nil {
+			t.Skipf("mysql not available: %v", err)
+		}
+		t.Cleanup(cleanup)
+		return db
+	default:
+		t.Fatalf("unknown backend %q", name)
+		return nil
+	}
+}
+
+// TestBookDatabases runs the same exercise against every BookDatabase
+// implementation so they stay behaviorally interchangeable.
+func TestBookDatabases(t *testing.T) {
+	for _, backend := range []string{"memory", "mongo", "mysql"} {
+		t.Run(backend, func(t *testing.T) {
+			db := newTestBookDatabase(t, backend)
+			ctx := context.Background()
+
+			book, err := db.AddBook(ctx, Book{Name: "Dune", Author: "Frank Herbert", Cost: 9.99})
+			if err != nil {
+				t.Fatalf("AddBook: %v", err)
+			}
+
+			got, err := db.GetBook(ctx, book.ID)
+			if err != nil {
+				t.Fatalf("GetBook: %v", err)
+			}
+			if got.Name != book.Name || got.Author != book.Author {
+				t.Fatalf("GetBook = %+v, want %+v", got, book)
+			}
+
+			result, err := db.ListBooks(ctx, BookQuery{Search: "Dune"})
+			if err != nil {
+				t.Fatalf("ListBooks: %v", err)
+			}
+			if len(result.Books) == 0 {
+				t.Fatalf("ListBooks returned no books")
+			}
+
+			book.Cost = 12.5
+			if err := db.UpdateBook(ctx, book); err != nil {
+				t.Fatalf("UpdateBook: %v", err)
+			}
+			got, err = db.GetBook(ctx, book.ID)
+			if err != nil {
+				t.Fatalf("GetBook after update: %v", err)
+			}
+			if got.Cost != 12.5 {
+				t.Fatalf("GetBook after update cost = %v, want 12.5", got.Cost)
+			}
+
+			if err := db.DeleteBook(ctx, book.ID); err != nil {
+				t.Fatalf("DeleteBook: %v", err)
+			}
+			if _, err := db.GetBook(ctx, book.ID); err != ErrBookNotFound {
+				t.Fatalf("GetBook after delete = %v, want ErrBookNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// bookMetadata is the enrichment payload returned by the external books API.
+type bookMetadata struct {
+	Author      string `json:"author"`
+	Description string `json:"description"`
+}
+
+// fetchBookMetadata looks up enrichment data for book from the external
+// books API configured via BOOKS_API_URL.
+func fetchBookMetadata(ctx context.Context, book Book) (bookMetadata, error) {
+	apiURL := os.Getenv("BOOKS_API_URL")
+	if apiURL == "" {
+		return bookMetadata{}, fmt.Errorf("BOOKS_API_URL is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s?title=%s", apiURL, url.QueryEscape(book.Name)), nil)
+	if err != nil {
+		return bookMetadata{}, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return bookMetadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return bookMetadata{}, fmt.Errorf("books API returned status %d", resp.StatusCode)
+	}
+
+	var meta bookMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return bookMetadata{}, err
+	}
+	return meta, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// filesystemStorage writes uploaded images under a local directory; main
+// serves that directory back at /uploads/ via http.FileServer.
+type filesystemStorage struct {
+	dir string
+}
+
+func newFilesystemStorage(dir string) (*filesystemStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &filesystemStorage{dir: dir}, nil
+}
+
+func (s *filesystemStorage) Save(ctx context.Context, name, contentType string, r io.Reader) (string, error) {
+	f, err := os.Create(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("/uploads/%s", name), nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+// gcsStorage writes uploaded images to a Google Cloud Storage bucket and
+// returns their public URL.
+type gcsStorage struct {
+	client *storage.Client
+	bucket string
+}
+
+func newGCSStorage(ctx context.Context, bucket string) (*gcsStorage, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("GCS_BUCKET is required for gcs image storage")
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsStorage{client: client, bucket: bucket}, nil
+}
+
+func (s *gcsStorage) Save(ctx context.Context, name, contentType string, r io.Reader) (string, error) {
+	w := s.client.Bucket(s.bucket).Object(name).NewWriter(ctx)
+	w.ContentType = contentType
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s", s.bucket, name), nil
+}
@@ -2,17 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/gorilla/csrf"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // User struct for storing user information
@@ -24,78 +23,147 @@ type User struct {
 
 // Book struct for storing book information
 type Book struct {
-	ID     primitive.ObjectID `bson:"_id,omitempty"`
-	Name   string             `bson:"name"`
-	Author string             `bson:"author"`
-	Cost   float64            `bson:"cost"`
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Author      string             `bson:"author"`
+	Cost        float64            `bson:"cost"`
+	ImageURL    string             `bson:"image_url,omitempty"`
+	Description string             `bson:"description,omitempty"`
 }
 
-// MongoDB configuration
-const (
-	ConnectionString = "mongodb://localhost:27017"
-	DatabaseName      = "mydatabase"
-	UsersCollection   = "users"
-	BooksCollection   = "books"
-)
+// app holds the dependencies shared by the HTTP handlers, replacing the
+// package-level database globals.
+type app struct {
+	books     BookDatabase
+	users     UserDatabase
+	images    Storage
+	publisher Publisher
+	worker    *worker
+}
 
-var (
-	client           *mongo.Client
-	usersCollection  *mongo.Collection
-	booksCollection  *mongo.Collection
-)
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-func init() {
-	// Create a MongoDB client
-	clientOptions := options.Client().ApplyURI(ConnectionString)
-	var err error
-	client, err = mongo.Connect(context.Background(), clientOptions)
+	books, users, cleanup, err := newDatabases(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer cleanup()
 
-	// Check the connection
-	err = client.Ping(context.Background(), readpref.Primary())
+	images, err := newStorage(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Get handles to the users and books collections
-	usersCollection = client.Database(DatabaseName).Collection(UsersCollection)
-	booksCollection = client.Database(DatabaseName).Collection(BooksCollection)
-}
+	publisher, err := newPublisher(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-func main() {
-	http.HandleFunc("/", loginHandler)
-	http.HandleFunc("/signup", signupHandler)
-	http.HandleFunc("/login", loginHandler)
-	http.HandleFunc("/book", bookHandler)
-	http.HandleFunc("/submit", submitBookHandler)
-	http.HandleFunc("/modify", modifyBookHandler)
-	http.HandleFunc("/delete", deleteBookHandler)
+	a := &app{books: books, users: users, images: images, publisher: publisher}
+	a.worker = newWorker(a, publisher)
+	a.worker.Start(ctx)
+	defer a.worker.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/", a.apiRouter())
+	mux.Handle("/uploads/", http.StripPrefix("/uploads/", http.FileServer(http.Dir(uploadsDir()))))
+	mux.HandleFunc("/health", a.healthHandler)
+	mux.HandleFunc("/", a.loginHandler)
+	mux.HandleFunc("/signup", a.signupHandler)
+	mux.HandleFunc("/login", a.loginHandler)
+	mux.HandleFunc("/logout", logoutHandler)
+	mux.HandleFunc("/book", authRequired(a.bookHandler))
+	mux.HandleFunc("/submit", authRequired(a.submitBookHandler))
+	mux.HandleFunc("/modify", authRequired(a.modifyBookHandler))
+	mux.HandleFunc("/delete", authRequired(a.deleteBookHandler))
+
+	csrfMiddleware := csrf.Protect(csrfAuthKey(), csrf.Secure(false))
 
 	fmt.Println("Server is running on http://localhost:8000")
-	log.Fatal(http.ListenAndServe(":8000", nil))
+	log.Fatal(http.ListenAndServe(":8000", csrfExemptAPI(mux, plaintextHTTP(csrfMiddleware(mux)))))
+}
+
+// plaintextHTTP marks each request as arriving over plain HTTP before it
+// reaches the CSRF middleware. csrf.Secure(false) only drops the Secure
+// attribute on the CSRF cookie; gorilla/csrf still assumes TLS for its
+// Referer/Origin check unless told otherwise, which would reject every
+// same-origin POST on this server since it never terminates TLS itself (see
+// the ListenAndServe call above).
+func plaintextHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, csrf.PlaintextHTTPRequest(r))
+	})
+}
+
+// csrfExemptAPI routes /api/v1/ requests straight to the handler, bypassing
+// CSRF protection. apiAuthRequired only accepts a JWT bearer token on that
+// prefix, never the browser session cookie, so a cross-site request can't
+// carry credentials a browser would attach automatically, and gorilla/csrf's
+// referer/token checks don't apply to it and would reject every non-GET
+// request from a legitimate API client.
+func csrfExemptAPI(handler, protected http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/") {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		protected.ServeHTTP(w, r)
+	})
+}
+
+// healthHandler reports the status of the database and background worker so
+// orchestrators can detect a degraded instance.
+func (a *app) healthHandler(w http.ResponseWriter, r *http.Request) {
+	status := struct {
+		Database string `json:"database"`
+		Worker   string `json:"worker"`
+	}{Database: "ok", Worker: "ok"}
+
+	if _, err := a.books.ListBooks(r.Context(), BookQuery{}); err != nil {
+		status.Database = "unavailable"
+	}
+	if !a.worker.Healthy() {
+		status.Worker = "unavailable"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status.Database != "ok" || status.Worker != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
 }
 
-func signupHandler(w http.ResponseWriter, r *http.Request) {
+func (a *app) signupHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
-		renderTemplate(w, "signup.html", nil)
+		renderTemplate(w, "signup.html", map[string]interface{}{
+			"CSRFField": csrf.TemplateField(r),
+		})
 	} else if r.Method == "POST" {
 		// Get form values
 		name := r.FormValue("name")
 		email := r.FormValue("email")
 		password := r.FormValue("password")
 
+		hashedPassword, err := hashPassword(password)
+		if err != nil {
+			http.Error(w, "Error creating user", http.StatusInternalServerError)
+			return
+		}
+
 		// Create a new user object
 		user := User{
 			Name:     name,
 			Email:    email,
-			Password: password,
+			Password: hashedPassword,
 		}
 
-		// Insert the user into the users collection
-		_, err := usersCollection.InsertOne(context.Background(), user)
-		if err != nil {
+		err = a.users.CreateUser(r.Context(), user)
+		if err == ErrUserExists {
+			http.Error(w, "Email already registered", http.StatusConflict)
+			return
+		} else if err != nil {
 			http.Error(w, "Error creating user", http.StatusInternalServerError)
 			return
 		}
@@ -105,57 +173,97 @@ func signupHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func loginHandler(w http.ResponseWriter, r *http.Request) {
+func (a *app) loginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
-		renderTemplate(w, "login.html", nil)
+		renderTemplate(w, "login.html", map[string]interface{}{
+			"CSRFField": csrf.TemplateField(r),
+		})
 	} else if r.Method == "POST" {
 		// Get form values
 		email := r.FormValue("email")
 		password := r.FormValue("password")
 
 		// Check if user exists
-		filter := bson.M{"email": email, "password": password}
-		var user User
-		err := usersCollection.FindOne(context.Background(), filter).Decode(&user)
-		if err != nil {
+		user, err := a.users.GetUserByEmail(r.Context(), email)
+		if err != nil || !checkPassword(user.Password, password) {
 			http.Error(w, "Invalid email or password", http.StatusUnauthorized)
 			return
 		}
 
+		// Establish a session for the logged-in user
+		session, _ := sessionStore.Get(r, sessionName)
+		session.Values[sessionUserKey] = user.Email
+		if err := session.Save(r, w); err != nil {
+			http.Error(w, "Failed to create session", http.StatusInternalServerError)
+			return
+		}
+
 		// Redirect to book page
 		http.Redirect(w, r, "/book", http.StatusSeeOther)
 	}
 }
 
-func bookHandler(w http.ResponseWriter, r *http.Request) {
-	// Retrieve books from the books collection
-	cursor, err := booksCollection.Find(context.Background(), bson.M{})
-	if err != nil {
-		http.Error(w, "Failed to retrieve books", http.StatusInternalServerError)
+func logoutHandler(w http.ResponseWriter, r *http.Request) {
+	session, _ := sessionStore.Get(r, sessionName)
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
 		return
 	}
-	defer cursor.Close(context.Background())
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
 
-	var books []Book
-	for cursor.Next(context.Background()) {
-		var book Book
-		if err := cursor.Decode(&book); err != nil {
-			http.Error(w, "Failed to decode book", http.StatusInternalServerError)
-			return
-		}
-		books = append(books, book)
+func (a *app) bookHandler(w http.ResponseWriter, r *http.Request) {
+	query := parseBookQuery(r.URL.Query())
+
+	// Retrieve books from the configured backend
+	result, err := a.books.ListBooks(r.Context(), query)
+	if err != nil {
+		http.Error(w, "Failed to retrieve books", http.StatusInternalServerError)
+		return
 	}
 
-	// Render the book page template with the book data
-	renderTemplate(w, "book.html", struct{ Books []Book }{books})
+	page, pageSize := normalizePaging(query.Page, query.PageSize)
+
+	// Render the book page template with the book data and pager controls
+	renderTemplate(w, "book.html", struct {
+		Books      []Book
+		CSRFField  template.HTML
+		Query      string
+		Sort       string
+		Page       int
+		PageSize   int
+		TotalCount int64
+		HasPrev    bool
+		HasNext    bool
+		PrevPage   int
+		NextPage   int
+	}{
+		Books:      result.Books,
+		CSRFField:  csrf.TemplateField(r),
+		Query:      query.Search,
+		Sort:       query.Sort,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: result.TotalCount,
+		HasPrev:    page > 1,
+		HasNext:    int64(page*pageSize) < result.TotalCount,
+		PrevPage:   page - 1,
+		NextPage:   page + 1,
+	})
 }
 
-func submitBookHandler(w http.ResponseWriter, r *http.Request) {
+func (a *app) submitBookHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	if err := r.ParseMultipartForm(maxImageSize); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, "Invalid form submission", http.StatusBadRequest)
+		return
+	}
+
 	// Retrieve form values
 	name := r.FormValue("name")
 	author := r.FormValue("author")
@@ -168,24 +276,34 @@ func submitBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	imageURL, err := saveUploadedImage(r.Context(), a.images, r)
+	if err != nil {
+		http.Error(w, "Invalid image upload", http.StatusBadRequest)
+		return
+	}
+
 	// Create a Book instance
 	book := Book{
-		Name:   name,
-		Author: author,
-		Cost:   bookCost,
+		Name:     name,
+		Author:   author,
+		Cost:     bookCost,
+		ImageURL: imageURL,
 	}
 
-	// Insert book into the books collection
-	_, err = booksCollection.InsertOne(context.Background(), book)
+	inserted, err := a.books.AddBook(r.Context(), book)
 	if err != nil {
 		http.Error(w, "Failed to insert book", http.StatusInternalServerError)
 		return
 	}
 
+	if err := a.publisher.Publish(r.Context(), inserted.ID.Hex()); err != nil {
+		log.Printf("worker: failed to publish book %s: %v", inserted.ID.Hex(), err)
+	}
+
 	http.Redirect(w, r, "/book", http.StatusSeeOther)
 }
 
-func modifyBookHandler(w http.ResponseWriter, r *http.Request) {
+func (a *app) modifyBookHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -197,6 +315,17 @@ func modifyBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	objID, err := primitive.ObjectIDFromHex(bookID)
+	if err != nil {
+		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxImageSize); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, "Invalid form submission", http.StatusBadRequest)
+		return
+	}
+
 	// Retrieve form values
 	name := r.FormValue("name")
 	author := r.FormValue("author")
@@ -209,24 +338,23 @@ func modifyBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update book details in the books collection
-	objID, err := primitive.ObjectIDFromHex(bookID)
+	existing, err := a.books.GetBook(r.Context(), objID)
 	if err != nil {
-		http.Error(w, "Invalid book ID", http.StatusBadRequest)
+		http.Error(w, "Book not found", http.StatusNotFound)
 		return
 	}
 
-	filter := bson.M{"_id": objID}
-	update := bson.M{
-		"$set": bson.M{
-			"name":   name,
-			"author": author,
-			"cost":   bookCost,
-		},
+	imageURL, err := saveUploadedImage(r.Context(), a.images, r)
+	if err != nil {
+		http.Error(w, "Invalid image upload", http.StatusBadRequest)
+		return
+	}
+	if imageURL == "" {
+		imageURL = existing.ImageURL
 	}
 
-	_, err = booksCollection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
+	book := Book{ID: objID, Name: name, Author: author, Cost: bookCost, ImageURL: imageURL, Description: existing.Description}
+	if err := a.books.UpdateBook(r.Context(), book); err != nil {
 		http.Error(w, "Failed to update book", http.StatusInternalServerError)
 		return
 	}
@@ -234,7 +362,7 @@ func modifyBookHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/book", http.StatusSeeOther)
 }
 
-func deleteBookHandler(w http.ResponseWriter, r *http.Request) {
+func (a *app) deleteBookHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -246,16 +374,13 @@ func deleteBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete book from the books collection
 	objID, err := primitive.ObjectIDFromHex(bookID)
 	if err != nil {
 		http.Error(w, "Invalid book ID", http.StatusBadRequest)
 		return
 	}
 
-	filter := bson.M{"_id": objID}
-	_, err = booksCollection.DeleteOne(context.Background(), filter)
-	if err != nil {
+	if err := a.books.DeleteBook(r.Context(), objID); err != nil {
 		http.Error(w, "Failed to delete book", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryDB is an in-process BookDatabase/UserDatabase backed by maps, useful
+// for tests and local development without a Mongo server.
+type memoryDB struct {
+	mu    sync.Mutex
+	books map[primitive.ObjectID]Book
+	users map[string]User
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		books: make(map[primitive.ObjectID]Book),
+		users: make(map[string]User),
+	}
+}
+
+func (d *memoryDB) ListBooks(ctx context.Context, query BookQuery) (BookListResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var matched []Book
+	for _, book := range d.books {
+		if bookMatchesSearch(book, query.Search) {
+			matched = append(matched, book)
+		}
+	}
+
+	total := int64(len(matched))
+	if total > maxCountCap {
+		total = maxCountCap
+	}
+
+	_, pageSize := normalizePaging(query.Page, query.PageSize)
+
+	if !query.Cursor.IsZero() {
+		sortBooks(matched, "")
+		var page []Book
+		for _, book := range matched {
+			if book.ID.Hex() > query.Cursor.Hex() {
+				page = append(page, book)
+				if len(page) == pageSize {
+					break
+				}
+			}
+		}
+		return BookListResult{Books: append([]Book(nil), page...), TotalCount: total}, nil
+	}
+
+	sortBooks(matched, query.Sort)
+
+	page, _ := normalizePaging(query.Page, query.PageSize)
+	start := (page - 1) * pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return BookListResult{Books: append([]Book(nil), matched[start:end]...), TotalCount: total}, nil
+}
+
+func (d *memoryDB) GetBook(ctx context.Context, id primitive.ObjectID) (Book, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	book, ok := d.books[id]
+	if !ok {
+		return Book{}, ErrBookNotFound
+	}
+	return book, nil
+}
+
+func (d *memoryDB) AddBook(ctx context.Context, book Book) (Book, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	book.ID = primitive.NewObjectID()
+	d.books[book.ID] = book
+	return book, nil
+}
+
+func (d *memoryDB) UpdateBook(ctx context.Context, book Book) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.books[book.ID]; !ok {
+		return ErrBookNotFound
+	}
+	d.books[book.ID] = book
+	return nil
+}
+
+func (d *memoryDB) DeleteBook(ctx context.Context, id primitive.ObjectID) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.books[id]; !ok {
+		return ErrBookNotFound
+	}
+	delete(d.books, id)
+	return nil
+}
+
+func (d *memoryDB) CreateUser(ctx context.Context, user User) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.users[user.Email]; ok {
+		return ErrUserExists
+	}
+	d.users[user.Email] = user
+	return nil
+}
+
+func (d *memoryDB) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	user, ok := d.users[email]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MongoDB configuration
+const (
+	ConnectionString = "mongodb://localhost:27017"
+	DatabaseName      = "mydatabase"
+	UsersCollection   = "users"
+	BooksCollection   = "books"
+)
+
+// mongoDB implements BookDatabase and UserDatabase against MongoDB.
+type mongoDB struct {
+	client *mongo.Client
+	users  *mongo.Collection
+	books  *mongo.Collection
+}
+
+func newMongoDB(ctx context.Context) (*mongoDB, *mongoDB, func(), error) {
+	clientOptions := options.Client().ApplyURI(ConnectionString)
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, nil, nil, err
+	}
+
+	db := &mongoDB{
+		client: client,
+		users:  client.Database(DatabaseName).Collection(UsersCollection),
+		books:  client.Database(DatabaseName).Collection(BooksCollection),
+	}
+	if err := db.ensureIndexes(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	cleanup := func() { client.Disconnect(context.Background()) }
+	return db, db, cleanup, nil
+}
+
+// ensureIndexes creates the indexes the app relies on. It is safe to call on
+// every startup since creating an existing index is a no-op.
+func (d *mongoDB) ensureIndexes(ctx context.Context) error {
+	userIndex := mongo.IndexModel{
+		Keys:    bson.M{"email": 1},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := d.users.Indexes().CreateOne(ctx, userIndex); err != nil {
+		return err
+	}
+
+	bookTextIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: "text"}, {Key: "author", Value: "text"}},
+	}
+	_, err := d.books.Indexes().CreateOne(ctx, bookTextIndex)
+	return err
+}
+
+func (d *mongoDB) ListBooks(ctx context.Context, query BookQuery) (BookListResult, error) {
+	page, pageSize := normalizePaging(query.Page, query.PageSize)
+	findOpts := options.Find().SetLimit(int64(pageSize))
+	if !query.Cursor.IsZero() {
+		findOpts.SetSort(bson.D{{Key: "_id", Value: 1}})
+	} else {
+		findOpts.SetSkip(int64((page - 1) * pageSize)).SetSort(mongoSortSpec(query.Sort))
+	}
+
+	searchFilter := bson.M{}
+	if query.Search != "" {
+		searchFilter = bson.M{"$text": bson.M{"$search": query.Search}}
+	}
+	listFilter := mongoWithCursorFilter(searchFilter, query.Cursor)
+
+	cursor, err := d.books.Find(ctx, listFilter, findOpts)
+	if err != nil && query.Search != "" {
+		// No text index available (e.g. freshly created collection);
+		// fall back to a case-insensitive regex match.
+		searchFilter = mongoSearchRegexFilter(query.Search)
+		listFilter = mongoWithCursorFilter(searchFilter, query.Cursor)
+		cursor, err = d.books.Find(ctx, listFilter, findOpts)
+	}
+	if err != nil {
+		return BookListResult{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var books []Book
+	for cursor.Next(ctx) {
+		var book Book
+		if err := cursor.Decode(&book); err != nil {
+			return BookListResult{}, err
+		}
+		books = append(books, book)
+	}
+	if err := cursor.Err(); err != nil {
+		return BookListResult{}, err
+	}
+
+	// TotalCount reflects the search filter alone, not the cursor, so "X of
+	// Y" stays meaningful across pages.
+	total, err := d.books.CountDocuments(ctx, searchFilter, options.Count().SetLimit(maxCountCap))
+	if err != nil {
+		return BookListResult{}, err
+	}
+
+	return BookListResult{Books: books, TotalCount: total}, nil
+}
+
+// mongoSortSpec translates a "?sort=" parameter into a Mongo sort document.
+func mongoSortSpec(sortParam string) bson.D {
+	field := sortParam
+	order := 1
+	if strings.HasPrefix(field, "-") {
+		field = field[1:]
+		order = -1
+	}
+	switch field {
+	case "name", "author", "cost":
+	default:
+		field = "_id"
+	}
+	return bson.D{{Key: field, Value: order}}
+}
+
+// mongoWithCursorFilter adds an "_id greater than cursor" condition to
+// filter, for the JSON API's keyset pagination. A zero cursor leaves filter
+// unchanged.
+func mongoWithCursorFilter(filter bson.M, cursor primitive.ObjectID) bson.M {
+	if cursor.IsZero() {
+		return filter
+	}
+	if len(filter) == 0 {
+		return bson.M{"_id": bson.M{"$gt": cursor}}
+	}
+	return bson.M{"$and": []bson.M{filter, {"_id": bson.M{"$gt": cursor}}}}
+}
+
+// mongoSearchRegexFilter is the fallback search used when no text index is
+// available.
+func mongoSearchRegexFilter(search string) bson.M {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+	return bson.M{"$or": []bson.M{
+		{"name": pattern},
+		{"author": pattern},
+	}}
+}
+
+func (d *mongoDB) GetBook(ctx context.Context, id primitive.ObjectID) (Book, error) {
+	var book Book
+	err := d.books.FindOne(ctx, bson.M{"_id": id}).Decode(&book)
+	if err == mongo.ErrNoDocuments {
+		return Book{}, ErrBookNotFound
+	}
+	return book, err
+}
+
+func (d *mongoDB) AddBook(ctx context.Context, book Book) (Book, error) {
+	res, err := d.books.InsertOne(ctx, book)
+	if err != nil {
+		return Book{}, err
+	}
+	book.ID = res.InsertedID.(primitive.ObjectID)
+	return book, nil
+}
+
+func (d *mongoDB) UpdateBook(ctx context.Context, book Book) error {
+	filter := bson.M{"_id": book.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        book.Name,
+			"author":      book.Author,
+			"cost":        book.Cost,
+			"image_url":   book.ImageURL,
+			"description": book.Description,
+		},
+	}
+	res, err := d.books.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+func (d *mongoDB) DeleteBook(ctx context.Context, id primitive.ObjectID) error {
+	res, err := d.books.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+func (d *mongoDB) CreateUser(ctx context.Context, user User) error {
+	_, err := d.users.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
+	}
+	return err
+}
+
+func (d *mongoDB) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	var user User
+	err := d.users.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return User{}, ErrUserNotFound
+	}
+	return user, err
+}
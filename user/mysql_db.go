@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mysqlDB implements BookDatabase and UserDatabase against MySQL. It expects
+// the following schema to already exist:
+//
+//	CREATE TABLE users (
+//		email    VARCHAR(255) PRIMARY KEY,
+//		name     VARCHAR(255) NOT NULL,
+//		password VARCHAR(255) NOT NULL
+//	);
+//	CREATE TABLE books (
+//		id          CHAR(24) PRIMARY KEY,
+//		name        VARCHAR(255) NOT NULL,
+//		author      VARCHAR(255) NOT NULL,
+//		cost        DOUBLE NOT NULL,
+//		image_url   VARCHAR(1024),
+//		description TEXT
+//	);
+type mysqlDB struct {
+	conn *sql.DB
+}
+
+func newMySQLDB(ctx context.Context) (*mysqlDB, *mysqlDB, func(), error) {
+	dsn := os.Getenv("MYSQL_DSN")
+	if dsn == "" {
+		dsn = "root:root@tcp(localhost:3306)/mydatabase"
+	}
+
+	conn, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
+	db := &mysqlDB{conn: conn}
+	cleanup := func() { db.conn.Close() }
+	return db, db, cleanup, nil
+}
+
+func (d *mysqlDB) ListBooks(ctx context.Context, query BookQuery) (BookListResult, error) {
+	page, pageSize := normalizePaging(query.Page, query.PageSize)
+
+	searchConds := []string{}
+	searchArgs := []interface{}{}
+	if query.Search != "" {
+		searchConds = append(searchConds, "(name LIKE ? OR author LIKE ?)")
+		like := "%" + query.Search + "%"
+		searchArgs = append(searchArgs, like, like)
+	}
+
+	listConds := append([]string{}, searchConds...)
+	listArgsPrefix := append([]interface{}{}, searchArgs...)
+	if !query.Cursor.IsZero() {
+		listConds = append(listConds, "id > ?")
+		listArgsPrefix = append(listArgsPrefix, query.Cursor.Hex())
+	}
+	listWhere := ""
+	if len(listConds) > 0 {
+		listWhere = "WHERE " + strings.Join(listConds, " AND ")
+	}
+
+	orderBy := mysqlOrderBy(query.Sort)
+	offset := (page - 1) * pageSize
+	if !query.Cursor.IsZero() {
+		// A cursor only has a stable meaning against the order books were
+		// inserted in, so keyset pagination always walks id ascending and
+		// never skips.
+		orderBy = "id ASC"
+		offset = 0
+	}
+
+	listQuery := fmt.Sprintf(
+		"SELECT id, name, author, cost, image_url, description FROM books %s ORDER BY %s LIMIT ? OFFSET ?",
+		listWhere, orderBy)
+	listArgs := append(append([]interface{}{}, listArgsPrefix...), pageSize, offset)
+
+	rows, err := d.conn.QueryContext(ctx, listQuery, listArgs...)
+	if err != nil {
+		return BookListResult{}, err
+	}
+	defer rows.Close()
+
+	var books []Book
+	for rows.Next() {
+		var book Book
+		var idHex string
+		var imageURL, description sql.NullString
+		if err := rows.Scan(&idHex, &book.Name, &book.Author, &book.Cost, &imageURL, &description); err != nil {
+			return BookListResult{}, err
+		}
+		if book.ID, err = primitive.ObjectIDFromHex(idHex); err != nil {
+			return BookListResult{}, err
+		}
+		book.ImageURL = imageURL.String
+		book.Description = description.String
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return BookListResult{}, err
+	}
+
+	// TotalCount reflects the search filter alone, not the cursor, so "X of
+	// Y" stays meaningful across pages.
+	searchWhere := ""
+	if len(searchConds) > 0 {
+		searchWhere = "WHERE " + strings.Join(searchConds, " AND ")
+	}
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT id FROM books %s LIMIT ?) capped", searchWhere)
+	countArgs := append(append([]interface{}{}, searchArgs...), maxCountCap)
+	var total int64
+	if err := d.conn.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return BookListResult{}, err
+	}
+
+	return BookListResult{Books: books, TotalCount: total}, nil
+}
+
+// mysqlOrderBy translates a "?sort=" parameter into an ORDER BY clause,
+// restricted to a known column allowlist to avoid injection via the field
+// name.
+func mysqlOrderBy(sortParam string) string {
+	field := sortParam
+	direction := "ASC"
+	if strings.HasPrefix(field, "-") {
+		field = field[1:]
+		direction = "DESC"
+	}
+	switch field {
+	case "name", "author", "cost":
+	default:
+		field = "id"
+	}
+	return fmt.Sprintf("%s %s", field, direction)
+}
+
+func (d *mysqlDB) GetBook(ctx context.Context, id primitive.ObjectID) (Book, error) {
+	book := Book{ID: id}
+	var imageURL, description sql.NullString
+	row := d.conn.QueryRowContext(ctx, "SELECT name, author, cost, image_url, description FROM books WHERE id = ?", id.Hex())
+	if err := row.Scan(&book.Name, &book.Author, &book.Cost, &imageURL, &description); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Book{}, ErrBookNotFound
+		}
+		return Book{}, err
+	}
+	book.ImageURL = imageURL.String
+	book.Description = description.String
+	return book, nil
+}
+
+func (d *mysqlDB) AddBook(ctx context.Context, book Book) (Book, error) {
+	book.ID = primitive.NewObjectID()
+	_, err := d.conn.ExecContext(ctx, "INSERT INTO books (id, name, author, cost, image_url, description) VALUES (?, ?, ?, ?, ?, ?)",
+		book.ID.Hex(), book.Name, book.Author, book.Cost, book.ImageURL, book.Description)
+	if err != nil {
+		return Book{}, err
+	}
+	return book, nil
+}
+
+func (d *mysqlDB) UpdateBook(ctx context.Context, book Book) error {
+	res, err := d.conn.ExecContext(ctx, "UPDATE books SET name = ?, author = ?, cost = ?, image_url = ?, description = ? WHERE id = ?",
+		book.Name, book.Author, book.Cost, book.ImageURL, book.Description, book.ID.Hex())
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+func (d *mysqlDB) DeleteBook(ctx context.Context, id primitive.ObjectID) error {
+	res, err := d.conn.ExecContext(ctx, "DELETE FROM books WHERE id = ?", id.Hex())
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+func (d *mysqlDB) CreateUser(ctx context.Context, user User) error {
+	_, err := d.conn.ExecContext(ctx, "INSERT INTO users (email, name, password) VALUES (?, ?, ?)",
+		user.Email, user.Name, user.Password)
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return ErrUserExists
+	}
+	return err
+}
+
+func (d *mysqlDB) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	user := User{Email: email}
+	row := d.conn.QueryRowContext(ctx, "SELECT name, password FROM users WHERE email = ?", email)
+	if err := row.Scan(&user.Name, &user.Password); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+	return user, nil
+}
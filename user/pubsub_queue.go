@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// pubsubQueue is a Publisher backed by a Google Cloud Pub/Sub topic and
+// subscription.
+type pubsubQueue struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+	sub    *pubsub.Subscription
+}
+
+func newPubsubQueue(ctx context.Context, projectID, topicID, subscriptionID string) (*pubsubQueue, error) {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pubsubQueue{
+		client: client,
+		topic:  client.Topic(topicID),
+		sub:    client.Subscription(subscriptionID),
+	}, nil
+}
+
+func (q *pubsubQueue) Publish(ctx context.Context, bookID string) error {
+	_, err := q.topic.Publish(ctx, &pubsub.Message{Data: []byte(bookID)}).Get(ctx)
+	return err
+}
+
+func (q *pubsubQueue) Subscribe(ctx context.Context, handler func(ctx context.Context, bookID string) error) error {
+	return q.sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		if err := handler(ctx, string(msg.Data)); err != nil {
+			log.Printf("worker: enrich %s: %v", string(msg.Data), err)
+			msg.Nack()
+			return
+		}
+		msg.Ack()
+	})
+}
+
+func (q *pubsubQueue) Close() error {
+	q.topic.Stop()
+	return q.client.Close()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// maxImageSize is the largest cover image upload accepted.
+const maxImageSize = 5 << 20 // 5 MB
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Storage saves an uploaded book cover image and returns the URL clients can
+// load it from.
+type Storage interface {
+	Save(ctx context.Context, name, contentType string, r io.Reader) (string, error)
+}
+
+// newStorage builds the Storage implementation selected by the
+// IMAGE_STORAGE environment variable ("filesystem" or "gcs"), defaulting to
+// "filesystem".
+func newStorage(ctx context.Context) (Storage, error) {
+	switch backend := os.Getenv("IMAGE_STORAGE"); backend {
+	case "", "filesystem":
+		return newFilesystemStorage(uploadsDir())
+	case "gcs":
+		return newGCSStorage(ctx, os.Getenv("GCS_BUCKET"))
+	default:
+		return nil, fmt.Errorf("unknown IMAGE_STORAGE %q", backend)
+	}
+}
+
+// uploadsDir is where filesystemStorage writes uploads and where main mounts
+// the file server that serves them back.
+func uploadsDir() string {
+	if dir := os.Getenv("UPLOADS_DIR"); dir != "" {
+		return dir
+	}
+	return "./uploads"
+}
+
+// saveUploadedImage reads the optional "image" multipart field, validates
+// it, and saves it to images. It returns an empty URL if no file was
+// attached.
+func saveUploadedImage(ctx context.Context, images Storage, r *http.Request) (string, error) {
+	file, header, err := r.FormFile("image")
+	if err == http.ErrMissingFile {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if header.Size > maxImageSize {
+		return "", fmt.Errorf("image exceeds maximum size of %d bytes", maxImageSize)
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if !allowedImageTypes[contentType] {
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	name := uuid.NewString() + filepath.Ext(header.Filename)
+	return images.Save(ctx, name, contentType, file)
+}
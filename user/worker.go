@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Publisher queues book IDs for asynchronous enrichment and delivers them to
+// a subscriber.
+type Publisher interface {
+	Publish(ctx context.Context, bookID string) error
+	// Subscribe blocks, invoking handler for each received message, until
+	// ctx is cancelled or an unrecoverable error occurs.
+	Subscribe(ctx context.Context, handler func(ctx context.Context, bookID string) error) error
+	Close() error
+}
+
+// newPublisher builds the Publisher implementation selected by the
+// PUBLISHER_BACKEND environment variable ("channel" or "pubsub"), defaulting
+// to "channel".
+func newPublisher(ctx context.Context) (Publisher, error) {
+	switch backend := os.Getenv("PUBLISHER_BACKEND"); backend {
+	case "", "channel":
+		return newChannelQueue(), nil
+	case "pubsub":
+		return newPubsubQueue(ctx, os.Getenv("GCP_PROJECT_ID"), os.Getenv("PUBSUB_TOPIC"), os.Getenv("PUBSUB_SUBSCRIPTION"))
+	default:
+		return nil, fmt.Errorf("unknown PUBLISHER_BACKEND %q", backend)
+	}
+}
+
+// worker consumes book IDs from a Publisher and enriches the corresponding
+// book with metadata from an external books API.
+type worker struct {
+	app       *app
+	publisher Publisher
+	healthy   atomic.Bool
+}
+
+func newWorker(a *app, publisher Publisher) *worker {
+	w := &worker{app: a, publisher: publisher}
+	w.healthy.Store(true)
+	return w
+}
+
+// Start runs the subscriber loop in a background goroutine until ctx is
+// cancelled.
+func (wk *worker) Start(ctx context.Context) {
+	go func() {
+		if err := wk.publisher.Subscribe(ctx, wk.enrichBook); err != nil && ctx.Err() == nil {
+			log.Printf("worker: subscriber stopped: %v", err)
+			wk.healthy.Store(false)
+		}
+	}()
+}
+
+// Stop releases the resources held by the underlying Publisher.
+func (wk *worker) Stop() error {
+	return wk.publisher.Close()
+}
+
+// Healthy reports whether the subscriber loop is still running.
+func (wk *worker) Healthy() bool {
+	return wk.healthy.Load()
+}
+
+func (wk *worker) enrichBook(ctx context.Context, bookIDHex string) error {
+	id, err := primitive.ObjectIDFromHex(bookIDHex)
+	if err != nil {
+		return err
+	}
+
+	book, err := wk.app.books.GetBook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	meta, err := fetchBookMetadata(ctx, book)
+	if err != nil {
+		return err
+	}
+
+	if meta.Author != "" {
+		book.Author = meta.Author
+	}
+	book.Description = meta.Description
+
+	return wk.app.books.UpdateBook(ctx, book)
+}